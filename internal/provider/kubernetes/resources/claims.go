@@ -0,0 +1,81 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+
+	"github.com/juju/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	modelUUIDLabel = "controller.juju.is/model-uuid"
+)
+
+// ManagedByJujuClaim is satisfied by any object labelled as managed by
+// juju, regardless of which model created it.
+func ManagedByJujuClaim() Claim {
+	return func(obj metav1.Object) (bool, error) {
+		return obj.GetLabels()[managedByLabel] == "juju", nil
+	}
+}
+
+// ModelUUIDClaim is satisfied by an object labelled with the given model
+// UUID, so one model doesn't adopt another model's resources.
+func ModelUUIDClaim(modelUUID string) Claim {
+	return func(obj metav1.Object) (bool, error) {
+		return obj.GetLabels()[modelUUIDLabel] == modelUUID, nil
+	}
+}
+
+// OwnedByClaim is satisfied by an object that carries an OwnerReference
+// pointing at owner.
+func OwnedByClaim(owner metav1.Object) Claim {
+	ownerUID := owner.GetUID()
+	return func(obj metav1.Object) (bool, error) {
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.UID == ownerUID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// assertClaims is the ClaimAsserter consulted by Apply: when opts carries
+// claims, it fetches the object currently in the cluster (if any) and
+// refuses to let Apply proceed unless every claim is satisfied. It is a
+// no-op, by design, when the object doesn't exist yet - that case is
+// handled by Apply falling through to Create.
+func assertClaims(ctx context.Context, r Resource, claims []Claim) error {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	existing := r.Clone()
+	if err := existing.Get(ctx); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Trace(err)
+	}
+
+	obj, ok := existing.(metav1.Object)
+	if !ok {
+		return nil
+	}
+
+	hasClaim, err := RunClaims(claims...).Assert(obj)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !hasClaim {
+		return errors.AlreadyExistsf(
+			"%s %q not controlled by juju (owned by %q)",
+			r.ID().Kind, r.ID().Name, obj.GetLabels()[managedByLabel])
+	}
+	return nil
+}
@@ -13,7 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
 
 	"github.com/juju/juju/core/status"
@@ -50,22 +50,26 @@ func (r *ClusterRole) ID() ID {
 	return ID{"ClusterRole", r.Name, r.Namespace}
 }
 
-// Apply patches the resource change.
-func (r *ClusterRole) Apply(ctx context.Context) error {
+// Apply patches the resource change. See preparePatch and applyConflict in
+// ssa.go for how claims, ServerSideApply and Force are handled.
+func (r *ClusterRole) Apply(ctx context.Context, opts ...ApplyOptions) error {
+	opt := applyOptions(opts)
+	patchOpts, err := preparePatch(ctx, r, opt)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &r.ClusterRole)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	res, err := r.client.Patch(ctx, r.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
-		FieldManager: JujuFieldManager,
-	})
+	res, err := r.client.Patch(ctx, r.Name, patchType(opt), data, patchOpts)
 	if k8serrors.IsNotFound(err) {
 		res, err = r.client.Create(ctx, &r.ClusterRole, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
 		})
 	}
 	if k8serrors.IsConflict(err) {
-		return errors.Annotatef(errConflict, "cluster role %q", r.Name)
+		return applyConflict("ClusterRole", r.Name, opt, err)
 	}
 	if err != nil {
 		return errors.Trace(err)
@@ -146,6 +150,25 @@ func (r *ClusterRole) ComputeStatus(_ context.Context, now time.Time) (string, s
 	return "", status.Active, now, nil
 }
 
+// Wait blocks until the cluster role reports status.Active, ctx is
+// cancelled or timeout elapses. A cluster role has no rollout to wait for,
+// so this returns as soon as it is observed to exist.
+func (r *ClusterRole) Wait(ctx context.Context, timeout time.Duration) error {
+	return watchUntilReady(ctx, timeout, r.Name,
+		func(ctx context.Context, lo metav1.ListOptions) (runtime.Object, error) {
+			return r.client.List(ctx, lo)
+		},
+		r.client.Watch,
+		func(event watch.Event) (bool, error) {
+			obj, ok := event.Object.(*rbacv1.ClusterRole)
+			if !ok {
+				return false, nil
+			}
+			r.ClusterRole = *obj
+			return obj.DeletionTimestamp == nil, nil
+		})
+}
+
 // Update updates the object in the Kubernetes cluster to the new representation
 func (r *ClusterRole) Update(ctx context.Context) error {
 	out, err := r.client.Update(
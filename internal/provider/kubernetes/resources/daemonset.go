@@ -13,11 +13,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 
 	"github.com/juju/juju/core/status"
 	k8sconstants "github.com/juju/juju/internal/provider/kubernetes/constants"
+	"github.com/juju/juju/internal/provider/kubernetes/resources/readycheck"
 )
 
 // DaemonSet extends the k8s daemonset.
@@ -51,22 +52,26 @@ func (ds *DaemonSet) ID() ID {
 	return ID{"DaemonSet", ds.Name, ds.Namespace}
 }
 
-// Apply patches the resource change.
-func (ds *DaemonSet) Apply(ctx context.Context) error {
+// Apply patches the resource change. See preparePatch and applyConflict in
+// ssa.go for how claims, ServerSideApply and Force are handled.
+func (ds *DaemonSet) Apply(ctx context.Context, opts ...ApplyOptions) error {
+	opt := applyOptions(opts)
+	patchOpts, err := preparePatch(ctx, ds, opt)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &ds.DaemonSet)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	res, err := ds.client.Patch(ctx, ds.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
-		FieldManager: JujuFieldManager,
-	})
+	res, err := ds.client.Patch(ctx, ds.Name, patchType(opt), data, patchOpts)
 	if k8serrors.IsNotFound(err) {
 		res, err = ds.client.Create(ctx, &ds.DaemonSet, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
 		})
 	}
 	if k8serrors.IsConflict(err) {
-		return errors.Annotatef(errConflict, "daemon set %q", ds.Name)
+		return applyConflict("DaemonSet", ds.Name, opt, err)
 	}
 	if err != nil {
 		return errors.Trace(err)
@@ -98,15 +103,94 @@ func (ds *DaemonSet) Delete(ctx context.Context) error {
 	return errors.Trace(err)
 }
 
+// Ensure ensures this daemon set exists in it's desired form inside the
+// cluster. If the object does not exist it's created and if the object
+// exists it's updated. The method also takes an optional set of claims to
+// test the exisiting Kubernetes object with to assert ownership before
+// overwriting it.
+func (ds *DaemonSet) Ensure(
+	ctx context.Context,
+	claims ...Claim,
+) ([]func(), error) {
+	cleanups := []func(){}
+	hasClaim := true
+
+	existing := DaemonSet{ds.client, ds.DaemonSet}
+	err := existing.Get(ctx)
+	if err == nil {
+		hasClaim, err = RunClaims(claims...).Assert(&existing.DaemonSet)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return cleanups, errors.Annotatef(
+			err,
+			"checking for existing daemon set %q",
+			existing.DaemonSet.Name,
+		)
+	}
+
+	if !hasClaim {
+		return cleanups, errors.AlreadyExistsf(
+			"daemon set %q not controlled by juju", ds.Name)
+	}
+
+	cleanups = append(cleanups, func() { _ = ds.Delete(ctx) })
+	if errors.IsNotFound(err) {
+		return cleanups, ds.Apply(ctx)
+	}
+
+	if err := ds.Update(ctx); err != nil {
+		return cleanups, err
+	}
+	return cleanups, nil
+}
+
+// Update updates the object in the Kubernetes cluster to the new representation
+func (ds *DaemonSet) Update(ctx context.Context) error {
+	out, err := ds.client.Update(
+		ctx,
+		&ds.DaemonSet,
+		metav1.UpdateOptions{
+			FieldManager: JujuFieldManager,
+		},
+	)
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "updating daemon set")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	ds.DaemonSet = *out
+	return nil
+}
+
 // ComputeStatus returns a juju status for the resource.
 func (ds *DaemonSet) ComputeStatus(ctx context.Context, now time.Time) (string, status.Status, time.Time, error) {
 	if ds.DeletionTimestamp != nil {
 		return "", status.Terminated, ds.DeletionTimestamp.Time, nil
 	}
-	if ds.Status.NumberReady == ds.Status.DesiredNumberScheduled {
+	ready, message := readycheck.DaemonSetReady(&ds.DaemonSet)
+	if ready {
 		return "", status.Active, now, nil
 	}
-	return "", status.Waiting, now, nil
+	return message, status.Waiting, now, nil
+}
+
+// Wait blocks until the daemon set reports status.Active, ctx is cancelled
+// or timeout elapses.
+func (ds *DaemonSet) Wait(ctx context.Context, timeout time.Duration) error {
+	return watchUntilReady(ctx, timeout, ds.Name,
+		func(ctx context.Context, lo metav1.ListOptions) (runtime.Object, error) {
+			return ds.client.List(ctx, lo)
+		},
+		ds.client.Watch,
+		func(event watch.Event) (bool, error) {
+			obj, ok := event.Object.(*appsv1.DaemonSet)
+			if !ok {
+				return false, nil
+			}
+			ds.DaemonSet = *obj
+			ready, _ := readycheck.DaemonSetReady(obj)
+			return ready && obj.DeletionTimestamp == nil, nil
+		})
 }
 
 // ListDaemonSets returns a list of daemon sets.
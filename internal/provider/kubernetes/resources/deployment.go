@@ -13,11 +13,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 
 	"github.com/juju/juju/core/status"
 	k8sconstants "github.com/juju/juju/internal/provider/kubernetes/constants"
+	"github.com/juju/juju/internal/provider/kubernetes/resources/readycheck"
 )
 
 // Deployment extends the k8s deployment.
@@ -51,22 +52,26 @@ func (d *Deployment) ID() ID {
 	return ID{"Deployment", d.Name, d.Namespace}
 }
 
-// Apply patches the resource change.
-func (d *Deployment) Apply(ctx context.Context) error {
+// Apply patches the resource change. See preparePatch and applyConflict in
+// ssa.go for how claims, ServerSideApply and Force are handled.
+func (d *Deployment) Apply(ctx context.Context, opts ...ApplyOptions) error {
+	opt := applyOptions(opts)
+	patchOpts, err := preparePatch(ctx, d, opt)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, &d.Deployment)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	res, err := d.client.Patch(ctx, d.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{
-		FieldManager: JujuFieldManager,
-	})
+	res, err := d.client.Patch(ctx, d.Name, patchType(opt), data, patchOpts)
 	if k8serrors.IsNotFound(err) {
 		res, err = d.client.Create(ctx, &d.Deployment, metav1.CreateOptions{
 			FieldManager: JujuFieldManager,
 		})
 	}
 	if k8serrors.IsConflict(err) {
-		return errors.Annotatef(errConflict, "deployment %q", d.Name)
+		return applyConflict("Deployment", d.Name, opt, err)
 	}
 	if err != nil {
 		return errors.Trace(err)
@@ -98,15 +103,94 @@ func (d *Deployment) Delete(ctx context.Context) error {
 	return errors.Trace(err)
 }
 
+// Ensure ensures this deployment exists in it's desired form inside the
+// cluster. If the object does not exist it's created and if the object
+// exists it's updated. The method also takes an optional set of claims to
+// test the exisiting Kubernetes object with to assert ownership before
+// overwriting it.
+func (d *Deployment) Ensure(
+	ctx context.Context,
+	claims ...Claim,
+) ([]func(), error) {
+	cleanups := []func(){}
+	hasClaim := true
+
+	existing := Deployment{d.client, d.Deployment}
+	err := existing.Get(ctx)
+	if err == nil {
+		hasClaim, err = RunClaims(claims...).Assert(&existing.Deployment)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return cleanups, errors.Annotatef(
+			err,
+			"checking for existing deployment %q",
+			existing.Deployment.Name,
+		)
+	}
+
+	if !hasClaim {
+		return cleanups, errors.AlreadyExistsf(
+			"deployment %q not controlled by juju", d.Name)
+	}
+
+	cleanups = append(cleanups, func() { _ = d.Delete(ctx) })
+	if errors.IsNotFound(err) {
+		return cleanups, d.Apply(ctx)
+	}
+
+	if err := d.Update(ctx); err != nil {
+		return cleanups, err
+	}
+	return cleanups, nil
+}
+
+// Update updates the object in the Kubernetes cluster to the new representation
+func (d *Deployment) Update(ctx context.Context) error {
+	out, err := d.client.Update(
+		ctx,
+		&d.Deployment,
+		metav1.UpdateOptions{
+			FieldManager: JujuFieldManager,
+		},
+	)
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "updating deployment")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	d.Deployment = *out
+	return nil
+}
+
 // ComputeStatus returns a juju status for the resource.
 func (d *Deployment) ComputeStatus(ctx context.Context, now time.Time) (string, status.Status, time.Time, error) {
 	if d.DeletionTimestamp != nil {
 		return "", status.Terminated, d.DeletionTimestamp.Time, nil
 	}
-	if d.Status.ReadyReplicas == d.Status.Replicas {
+	ready, message := readycheck.DeploymentReady(&d.Deployment)
+	if ready {
 		return "", status.Active, now, nil
 	}
-	return "", status.Waiting, now, nil
+	return message, status.Waiting, now, nil
+}
+
+// Wait blocks until the deployment reports status.Active, ctx is cancelled
+// or timeout elapses.
+func (d *Deployment) Wait(ctx context.Context, timeout time.Duration) error {
+	return watchUntilReady(ctx, timeout, d.Name,
+		func(ctx context.Context, lo metav1.ListOptions) (runtime.Object, error) {
+			return d.client.List(ctx, lo)
+		},
+		d.client.Watch,
+		func(event watch.Event) (bool, error) {
+			dep, ok := event.Object.(*appsv1.Deployment)
+			if !ok {
+				return false, nil
+			}
+			d.Deployment = *dep
+			ready, _ := readycheck.DeploymentReady(dep)
+			return ready && dep.DeletionTimestamp == nil, nil
+		})
 }
 
 // ListDeployments returns a list of deployments.
@@ -0,0 +1,299 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/juju/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	memory "k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/juju/juju/core/status"
+	k8sconstants "github.com/juju/juju/internal/provider/kubernetes/constants"
+)
+
+// NewCachedRESTMapper returns a RESTMapper backed by a local cache of the
+// API server's discovery data, refetching lazily the first time a GVK
+// isn't found. DynamicResource uses this to resolve a GroupVersionKind to
+// its GroupVersionResource without a discovery round trip per call.
+func NewCachedRESTMapper(client discovery.DiscoveryInterface) meta.RESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client))
+}
+
+// DynamicResource wraps an arbitrary Kubernetes object (typically a Custom
+// Resource, e.g. an Istio VirtualService or a cert-manager Certificate) so
+// it can be driven through the same Resource interface as the hand-written
+// kinds, without the package needing a typed client for every CRD a charm
+// might want to manage.
+type DynamicResource struct {
+	client   dynamic.ResourceInterface
+	gvk      schema.GroupVersionKind
+	registry *Registry
+	unstructured.Unstructured
+}
+
+// NewDynamicResource resolves gvk to a GroupVersionResource via mapper and
+// returns a DynamicResource for name in namespace. namespace is ignored for
+// cluster-scoped kinds. registry may be nil, in which case ComputeStatus
+// always falls back to the standard Ready-condition convention.
+func NewDynamicResource(
+	mapper meta.RESTMapper,
+	client dynamic.Interface,
+	registry *Registry,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+	in *unstructured.Unstructured,
+) (*DynamicResource, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, errors.Annotatef(err, "resolving REST mapping for %s", gvk)
+	}
+
+	var resourceClient dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resourceClient = client.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	if in == nil {
+		in = &unstructured.Unstructured{}
+	}
+	in.SetGroupVersionKind(gvk)
+	in.SetName(name)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		in.SetNamespace(namespace)
+	}
+	return &DynamicResource{client: resourceClient, gvk: gvk, registry: registry, Unstructured: *in}, nil
+}
+
+func (r *DynamicResource) DeleteOrphan(ctx context.Context) error {
+	return nil
+}
+
+// Clone returns a copy of the resource.
+func (r *DynamicResource) Clone() Resource {
+	clone := *r
+	clone.Unstructured = *r.Unstructured.DeepCopy()
+	return &clone
+}
+
+// ID returns a comparable ID for the Resource.
+func (r *DynamicResource) ID() ID {
+	return ID{r.gvk.Kind, r.GetName(), r.GetNamespace()}
+}
+
+// Apply patches the resource change. See preparePatch and applyConflict in
+// ssa.go for how claims, ServerSideApply and Force are handled.
+func (r *DynamicResource) Apply(ctx context.Context, opts ...ApplyOptions) error {
+	opt := applyOptions(opts)
+	patchOpts, err := preparePatch(ctx, r, opt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, err := r.Unstructured.MarshalJSON()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, err := r.client.Patch(ctx, r.GetName(), patchType(opt), data, patchOpts)
+	if k8serrors.IsNotFound(err) {
+		res, err = r.client.Create(ctx, &r.Unstructured, metav1.CreateOptions{
+			FieldManager: JujuFieldManager,
+		})
+	}
+	if k8serrors.IsConflict(err) {
+		return applyConflict(r.gvk.Kind, r.GetName(), opt, err)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.Unstructured = *res
+	return nil
+}
+
+// Get refreshes the resource.
+func (r *DynamicResource) Get(ctx context.Context) error {
+	res, err := r.client.Get(ctx, r.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "k8s")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	r.Unstructured = *res
+	return nil
+}
+
+// Delete removes the resource.
+func (r *DynamicResource) Delete(ctx context.Context) error {
+	err := r.client.Delete(ctx, r.GetName(), metav1.DeleteOptions{
+		PropagationPolicy: k8sconstants.DefaultPropagationPolicy(),
+	})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "k8s "+r.gvk.Kind+" for deletion")
+	}
+	return errors.Trace(err)
+}
+
+// Ensure ensures this resource exists in its desired form inside the
+// cluster, refusing to overwrite an existing object that fails claims.
+func (r *DynamicResource) Ensure(ctx context.Context, claims ...Claim) ([]func(), error) {
+	cleanups := []func(){}
+	hasClaim := true
+
+	existing := r.Clone().(*DynamicResource)
+	err := existing.Get(ctx)
+	if err == nil {
+		hasClaim, err = RunClaims(claims...).Assert(&existing.Unstructured)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return cleanups, errors.Annotatef(err, "checking for existing %s %q", r.gvk.Kind, r.GetName())
+	}
+
+	if !hasClaim {
+		return cleanups, errors.AlreadyExistsf("%s %q not controlled by juju", r.gvk.Kind, r.GetName())
+	}
+
+	cleanups = append(cleanups, func() { _ = r.Delete(ctx) })
+	if errors.IsNotFound(err) {
+		return cleanups, r.Apply(ctx)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		return cleanups, err
+	}
+	return cleanups, nil
+}
+
+// Update updates the object in the Kubernetes cluster to the new
+// representation.
+func (r *DynamicResource) Update(ctx context.Context) error {
+	out, err := r.client.Update(ctx, &r.Unstructured, metav1.UpdateOptions{
+		FieldManager: JujuFieldManager,
+	})
+	if k8serrors.IsNotFound(err) {
+		return errors.NewNotFound(err, "updating "+r.gvk.Kind)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	r.Unstructured = *out
+	return nil
+}
+
+// Wait blocks until ComputeStatus reports status.Active, ctx is cancelled
+// or timeout elapses.
+func (r *DynamicResource) Wait(ctx context.Context, timeout time.Duration) error {
+	return watchUntilReady(ctx, timeout, r.GetName(),
+		func(ctx context.Context, lo metav1.ListOptions) (runtime.Object, error) {
+			return r.client.List(ctx, lo)
+		},
+		r.client.Watch,
+		func(event watch.Event) (bool, error) {
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				return false, nil
+			}
+			r.Unstructured = *obj
+			_, s, _, err := r.ComputeStatus(ctx, time.Now())
+			if err != nil {
+				return false, err
+			}
+			return s == status.Active, nil
+		})
+}
+
+// ComputeStatus returns a juju status for the resource. Kinds registered in
+// the resource's Registry use their custom ComputeStatusFunc; everything
+// else is read from a standard status.conditions[type=Ready] condition,
+// falling back to Available or Succeeded for CRDs that predate the Ready
+// convention.
+func (r *DynamicResource) ComputeStatus(ctx context.Context, now time.Time) (string, status.Status, time.Time, error) {
+	if r.GetDeletionTimestamp() != nil {
+		return "", status.Terminated, r.GetDeletionTimestamp().Time, nil
+	}
+	if r.registry != nil {
+		if fn, ok := r.registry.ComputeStatusFunc(r.gvk); ok {
+			return fn(ctx, &r.Unstructured, now)
+		}
+	}
+	ready, message, found := readyCondition(&r.Unstructured, "Ready", "Available", "Succeeded")
+	if !found {
+		return "status unknown: no Ready/Available/Succeeded condition reported", status.Waiting, now, nil
+	}
+	if ready {
+		return "", status.Active, now, nil
+	}
+	return message, status.Waiting, now, nil
+}
+
+// readyCondition looks for the first matching condition (in order) in
+// status.conditions and reports whether it is True, along with its
+// message.
+func readyCondition(u *unstructured.Unstructured, conditionTypes ...string) (ready bool, message string, found bool) {
+	conditions, ok, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if !ok || err != nil {
+		return false, "", false
+	}
+	for _, wantType := range conditionTypes {
+		for _, raw := range conditions {
+			cond, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			condType, _, _ := unstructured.NestedString(cond, "type")
+			if condType != wantType {
+				continue
+			}
+			condStatus, _, _ := unstructured.NestedString(cond, "status")
+			condMessage, _, _ := unstructured.NestedString(cond, "message")
+			return condStatus == "True", condMessage, true
+		}
+	}
+	return false, "", false
+}
+
+// ListDynamicResources returns a list of resources of gvk in namespace.
+func ListDynamicResources(
+	ctx context.Context,
+	mapper meta.RESTMapper,
+	client dynamic.Interface,
+	registry *Registry,
+	gvk schema.GroupVersionKind,
+	namespace string,
+	opts metav1.ListOptions,
+) ([]DynamicResource, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, errors.Annotatef(err, "resolving REST mapping for %s", gvk)
+	}
+	var resourceClient dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resourceClient = client.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	var items []DynamicResource
+	for {
+		res, err := resourceClient.List(ctx, opts)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, item := range res.Items {
+			item := item
+			items = append(items, DynamicResource{client: resourceClient, gvk: gvk, registry: registry, Unstructured: item})
+		}
+		if res.GetContinue() == "" {
+			break
+		}
+		opts.Continue = res.GetContinue()
+	}
+	return items, nil
+}
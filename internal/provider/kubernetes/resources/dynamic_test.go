@@ -0,0 +1,162 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/juju/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/juju/juju/internal/provider/kubernetes/resources"
+)
+
+var widgetGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+var widgetGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+// singleResourceRESTMapper is a meta.RESTMapper stub that only knows about
+// one GroupVersionResource, which is all NewDynamicResource needs to
+// resolve widgetGVK in these tests.
+type singleResourceRESTMapper struct {
+	gvk schema.GroupVersionKind
+	gvr schema.GroupVersionResource
+}
+
+func (m *singleResourceRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	if gk != m.gvk.GroupKind() {
+		return nil, fmt.Errorf("no mapping for %s", gk)
+	}
+	return &meta.RESTMapping{
+		Resource:         m.gvr,
+		GroupVersionKind: m.gvk,
+		Scope:            meta.RESTScopeNamespace,
+	}, nil
+}
+
+func (m *singleResourceRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	mapping, err := m.RESTMapping(gk, versions...)
+	if err != nil {
+		return nil, err
+	}
+	return []*meta.RESTMapping{mapping}, nil
+}
+
+func (m *singleResourceRESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	return m.gvk, nil
+}
+
+func (m *singleResourceRESTMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	return []schema.GroupVersionKind{m.gvk}, nil
+}
+
+func (m *singleResourceRESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	return m.gvr, nil
+}
+
+func (m *singleResourceRESTMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	return []schema.GroupVersionResource{m.gvr}, nil
+}
+
+func (m *singleResourceRESTMapper) ResourceSingularizer(resource string) (string, error) {
+	return resource, nil
+}
+
+func newWidget(name, namespace string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(widgetGVK)
+	u.SetName(name)
+	u.SetNamespace(namespace)
+	u.SetLabels(labels)
+	return u
+}
+
+func newFakeDynamicResource(objects ...runtime.Object) (*resources.DynamicResource, *dynamicfake.FakeDynamicClient) {
+	scheme := runtime.NewScheme()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"}, objects...)
+	mapper := &singleResourceRESTMapper{gvk: widgetGVK, gvr: widgetGVR}
+
+	desired := newWidget("widget1", "ns1", nil)
+	r, err := resources.NewDynamicResource(mapper, client, nil, widgetGVK, "ns1", "widget1", desired)
+	if err != nil {
+		panic(err)
+	}
+	return r, client
+}
+
+func TestDynamicResourceEnsureRejectsExistingObjectThatFailsClaim(t *testing.T) {
+	existing := newWidget("widget1", "ns1", nil)
+	existing.SetAnnotations(map[string]string{"seen": "before"})
+	r, client := newFakeDynamicResource(existing)
+	r.SetAnnotations(map[string]string{"seen": "after"})
+
+	_, err := r.Ensure(context.Background(), resources.ManagedByJujuClaim())
+	if !errors.IsAlreadyExists(err) {
+		t.Fatalf("got err %v, want AlreadyExists", err)
+	}
+
+	// Ensure must have rejected the claim before ever reaching Update: the
+	// object in the cluster should be untouched, not overwritten with r's
+	// desired state.
+	got, err := client.Resource(widgetGVR).Namespace("ns1").Get(context.Background(), "widget1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("fetching widget1: %v", err)
+	}
+	if got.GetAnnotations()["seen"] != "before" {
+		t.Fatalf("existing object should not have been updated: got annotations %v", got.GetAnnotations())
+	}
+}
+
+func TestDynamicResourceEnsureUpdatesExistingClaimedObject(t *testing.T) {
+	existing := newWidget("widget1", "ns1", map[string]string{"app.kubernetes.io/managed-by": "juju"})
+	existing.SetAnnotations(map[string]string{"seen": "before"})
+	r, client := newFakeDynamicResource(existing)
+	r.SetAnnotations(map[string]string{"seen": "after"})
+
+	if _, err := r.Ensure(context.Background(), resources.ManagedByJujuClaim()); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+
+	got, err := client.Resource(widgetGVR).Namespace("ns1").Get(context.Background(), "widget1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("fetching widget1: %v", err)
+	}
+	if got.GetAnnotations()["seen"] != "after" {
+		t.Fatalf("existing claimed object was not updated: got annotations %v", got.GetAnnotations())
+	}
+}
+
+func TestDynamicResourceApplyServerSideApplyConflict(t *testing.T) {
+	r, client := newFakeDynamicResource()
+	client.PrependReactor("patch", "widgets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &k8serrors.StatusError{
+			ErrStatus: metav1.Status{
+				Status: metav1.StatusFailure,
+				Reason: metav1.StatusReasonConflict,
+				Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{
+					Type:    "FieldManagerConflict",
+					Message: `conflict with "flux" using example.com/v1: .spec.replicas`,
+				}}},
+			},
+		}
+	})
+
+	err := r.Apply(context.Background(), resources.ApplyOptions{ServerSideApply: true})
+	conflicts, ok := resources.FieldConflicts(err)
+	if !ok {
+		t.Fatalf("got err %v, want an applyConflictError", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Manager != "flux" || conflicts[0].Field != ".spec.replicas" {
+		t.Fatalf("got conflicts %+v, want a single flux/.spec.replicas conflict", conflicts)
+	}
+}
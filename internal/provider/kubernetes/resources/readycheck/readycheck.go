@@ -0,0 +1,204 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package readycheck centralises the readiness logic used by the
+// ComputeStatus methods of the various Kubernetes resource wrappers. The
+// rules here are modelled on Helm 3.5's kube.ReadyChecker, which looks past
+// the handful of top level counters (e.g. ReadyReplicas == Replicas) that
+// are too coarse to notice a stuck rollout, an unobserved spec change or a
+// partitioned StatefulSet update.
+package readycheck
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DeploymentReady reports whether a Deployment has finished rolling out.
+func DeploymentReady(d *appsv1.Deployment) (bool, string) {
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, "waiting for rollout to be observed"
+	}
+
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse {
+			return false, fmt.Sprintf("rollout is not progressing: %s", cond.Reason)
+		}
+	}
+
+	var replicas int32 = 1
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d updated replicas are available",
+			d.Status.UpdatedReplicas, replicas)
+	}
+
+	expectedReady := replicas - maxUnavailable(d)
+	if d.Status.AvailableReplicas < expectedReady {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d replicas are available",
+			d.Status.AvailableReplicas, expectedReady)
+	}
+	return true, ""
+}
+
+// maxUnavailable returns the resolved MaxUnavailable from the Deployment's
+// RollingUpdate strategy, defaulting to 0 when unset or when the strategy
+// is Recreate.
+func maxUnavailable(d *appsv1.Deployment) int32 {
+	if d.Spec.Strategy.Type != appsv1.RollingUpdateDeploymentStrategyType ||
+		d.Spec.Strategy.RollingUpdate == nil {
+		return 0
+	}
+	var replicas int32 = 1
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	maxUnavailable, _ := intstr.GetScaledValueFromIntOrPercent(
+		d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(replicas), false)
+	return int32(maxUnavailable)
+}
+
+// DaemonSetReady reports whether a DaemonSet has finished rolling out to
+// every scheduled node.
+func DaemonSetReady(ds *appsv1.DaemonSet) (bool, string) {
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return false, "waiting for rollout to be observed"
+	}
+
+	maxUnavailable := daemonSetMaxUnavailable(ds)
+	expectedReady := ds.Status.DesiredNumberScheduled - maxUnavailable
+
+	if ds.Status.UpdatedNumberScheduled < expectedReady {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d updated pods are scheduled",
+			ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+	}
+	if ds.Status.NumberReady < expectedReady {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d pods are ready",
+			ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+	}
+	return true, ""
+}
+
+func daemonSetMaxUnavailable(ds *appsv1.DaemonSet) int32 {
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType ||
+		ds.Spec.UpdateStrategy.RollingUpdate == nil {
+		return 0
+	}
+	maxUnavailable, _ := intstr.GetScaledValueFromIntOrPercent(
+		ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, int(ds.Status.DesiredNumberScheduled), false)
+	return int32(maxUnavailable)
+}
+
+// StatefulSetReady reports whether a StatefulSet has finished rolling out.
+// When the update strategy uses a partition, only the replicas at or above
+// the partition ordinal are required to be updated and ready.
+func StatefulSetReady(ss *appsv1.StatefulSet) (bool, string) {
+	if ss.Status.ObservedGeneration < ss.Generation {
+		return false, "waiting for rollout to be observed"
+	}
+
+	var replicas int32 = 1
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+
+	var partition int32
+	if ss.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType &&
+		ss.Spec.UpdateStrategy.RollingUpdate != nil &&
+		ss.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = *ss.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+	expectedUpdated := replicas - partition
+
+	if ss.Status.UpdatedReplicas < expectedUpdated {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d updated replicas are available",
+			ss.Status.UpdatedReplicas, expectedUpdated)
+	}
+	if ss.Status.ReadyReplicas < expectedUpdated {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d replicas are ready",
+			ss.Status.ReadyReplicas, expectedUpdated)
+	}
+	return true, ""
+}
+
+// ReplicaSetReady reports whether a ReplicaSet has scaled up all of its
+// replicas.
+func ReplicaSetReady(rs *appsv1.ReplicaSet) (bool, string) {
+	if rs.Status.ObservedGeneration < rs.Generation {
+		return false, "waiting for rollout to be observed"
+	}
+	var replicas int32 = 1
+	if rs.Spec.Replicas != nil {
+		replicas = *rs.Spec.Replicas
+	}
+	if rs.Status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("waiting for rollout: %d of %d replicas are ready",
+			rs.Status.ReadyReplicas, replicas)
+	}
+	return true, ""
+}
+
+// PodReady reports whether a Pod is considered ready, by inspecting the
+// PodReady condition rather than just the phase.
+func PodReady(pod *corev1.Pod) (bool, string) {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return true, ""
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			if cond.Status == corev1.ConditionTrue {
+				return true, ""
+			}
+			return false, fmt.Sprintf("waiting for pod to be ready: %s", cond.Reason)
+		}
+	}
+	return false, "waiting for pod readiness to be reported"
+}
+
+// PVCReady reports whether a PersistentVolumeClaim has been bound.
+func PVCReady(pvc *corev1.PersistentVolumeClaim) (bool, string) {
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, fmt.Sprintf("waiting for persistent volume claim to be bound: currently %s", pvc.Status.Phase)
+	}
+	return true, ""
+}
+
+// ServiceReady reports whether a Service is ready. ClusterIP and headless
+// services are ready as soon as they exist; LoadBalancer services must have
+// an ingress address assigned.
+func ServiceReady(svc *corev1.Service) (bool, string) {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return true, ""
+	}
+	if len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return false, "waiting for load balancer ingress to be assigned"
+	}
+	return true, ""
+}
+
+// JobReady reports whether a Job has completed successfully.
+func JobReady(job *batchv1.Job) (bool, string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return false, fmt.Sprintf("job has failed: %s", cond.Reason)
+		}
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true, ""
+		}
+	}
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	if job.Status.Succeeded < completions {
+		return false, fmt.Sprintf("waiting for job: %d of %d completions succeeded",
+			job.Status.Succeeded, completions)
+	}
+	return true, ""
+}
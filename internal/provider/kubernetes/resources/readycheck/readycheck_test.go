@@ -0,0 +1,151 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package readycheck_test
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/juju/juju/internal/provider/kubernetes/resources/readycheck"
+)
+
+func int32ptr(i int32) *int32 { return &i }
+
+func TestDeploymentReady(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     *appsv1.Deployment
+		ready bool
+	}{
+		{
+			name: "unobserved generation",
+			d: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+			},
+		},
+		{
+			name: "not progressing",
+			d: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+		},
+		{
+			name: "updated replicas behind spec",
+			d: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: int32ptr(3)},
+				Status: appsv1.DeploymentStatus{UpdatedReplicas: 2},
+			},
+		},
+		{
+			name: "available replicas below maxUnavailable threshold",
+			d: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: int32ptr(4),
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDeployment{
+							MaxUnavailable: intOrStr(1),
+						},
+					},
+				},
+				Status: appsv1.DeploymentStatus{
+					UpdatedReplicas:   4,
+					AvailableReplicas: 2,
+				},
+			},
+		},
+		{
+			name: "ready",
+			d: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: int32ptr(4),
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDeployment{
+							MaxUnavailable: intOrStr(1),
+						},
+					},
+				},
+				Status: appsv1.DeploymentStatus{
+					UpdatedReplicas:   4,
+					AvailableReplicas: 3,
+				},
+			},
+			ready: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ready, message := readycheck.DeploymentReady(tc.d)
+			if ready != tc.ready {
+				t.Fatalf("DeploymentReady() = %v (%q), want %v", ready, message, tc.ready)
+			}
+		})
+	}
+}
+
+func TestDaemonSetReady(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: intOrStr(1)},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 5,
+			UpdatedNumberScheduled: 4,
+			NumberReady:            4,
+		},
+	}
+	if ready, _ := readycheck.DaemonSetReady(ds); !ready {
+		t.Fatalf("expected daemon set within MaxUnavailable to be ready")
+	}
+
+	ds.Status.NumberReady = 3
+	if ready, _ := readycheck.DaemonSetReady(ds); ready {
+		t.Fatalf("expected daemon set short of the MaxUnavailable threshold to be not ready")
+	}
+}
+
+func TestStatefulSetReadyPartition(t *testing.T) {
+	ss := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32ptr(5),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+					Partition: int32ptr(3),
+				},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			UpdatedReplicas: 2,
+			ReadyReplicas:   5,
+		},
+	}
+	if ready, msg := readycheck.StatefulSetReady(ss); !ready {
+		t.Fatalf("expected partitioned statefulset to be ready, got message %q", msg)
+	}
+
+	ss.Status.UpdatedReplicas = 1
+	if ready, _ := readycheck.StatefulSetReady(ss); ready {
+		t.Fatalf("expected statefulset with fewer than replicas-partition updated to be not ready")
+	}
+}
+
+func intOrStr(i int) *intstr.IntOrString {
+	v := intstr.FromInt(i)
+	return &v
+}
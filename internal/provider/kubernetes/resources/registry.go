@@ -0,0 +1,49 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/juju/juju/core/status"
+)
+
+// ComputeStatusFunc computes a juju status for a Custom Resource that
+// doesn't follow the standard status.conditions[type=Ready] convention.
+// It has the same shape as Resource.ComputeStatus.
+type ComputeStatusFunc func(ctx context.Context, u *unstructured.Unstructured, now time.Time) (string, status.Status, time.Time, error)
+
+// Registry lets callers register a ComputeStatusFunc for a GVK that needs
+// bespoke readiness logic, so DynamicResource doesn't have to hardcode
+// every CRD convention it might encounter.
+type Registry struct {
+	mu    sync.RWMutex
+	funcs map[schema.GroupVersionKind]ComputeStatusFunc
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{funcs: make(map[schema.GroupVersionKind]ComputeStatusFunc)}
+}
+
+// RegisterComputeStatus registers fn as the ComputeStatusFunc for gvk,
+// replacing any previous registration.
+func (r *Registry) RegisterComputeStatus(gvk schema.GroupVersionKind, fn ComputeStatusFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.funcs[gvk] = fn
+}
+
+// ComputeStatusFunc returns the registered func for gvk, if any.
+func (r *Registry) ComputeStatusFunc(gvk schema.GroupVersionKind) (ComputeStatusFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.funcs[gvk]
+	return fn, ok
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	goerrors "errors"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func conflictStatusErr(causes ...metav1.StatusCause) error {
+	return &k8serrors.StatusError{
+		ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonConflict,
+			Details: &metav1.StatusDetails{Causes: causes},
+		},
+	}
+}
+
+func TestParseSSAConflictsExtractsManagerAndField(t *testing.T) {
+	err := conflictStatusErr(metav1.StatusCause{
+		Type:    "FieldManagerConflict",
+		Message: `conflict with "flux" using apps/v1: .spec.replicas`,
+	})
+
+	conflictErr := parseSSAConflicts("Deployment", "my-app", err)
+	conflicts, ok := FieldConflicts(conflictErr)
+	if !ok {
+		t.Fatalf("FieldConflicts did not recognise %v as a conflict error", conflictErr)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want 1: %+v", len(conflicts), conflicts)
+	}
+	if conflicts[0].Manager != "flux" || conflicts[0].Field != ".spec.replicas" {
+		t.Fatalf("got %+v, want Manager=flux Field=.spec.replicas", conflicts[0])
+	}
+}
+
+func TestParseSSAConflictsSkipsUnrecognisedCauses(t *testing.T) {
+	err := conflictStatusErr(
+		metav1.StatusCause{Type: "FieldManagerConflict", Message: "not in the expected format"},
+		metav1.StatusCause{Type: metav1.CauseTypeFieldValueInvalid, Message: `conflict with "argocd": .spec.template`},
+	)
+
+	conflictErr := parseSSAConflicts("Deployment", "my-app", err)
+	conflicts, ok := FieldConflicts(conflictErr)
+	if !ok {
+		t.Fatalf("FieldConflicts did not recognise %v as a conflict error", conflictErr)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("got %d conflicts, want 0 since neither cause matches both type and message format: %+v",
+			len(conflicts), conflicts)
+	}
+}
+
+func TestParseSSAConflictsNoCauses(t *testing.T) {
+	conflictErr := parseSSAConflicts("Deployment", "my-app", k8serrors.NewConflict(
+		schema.GroupResource{Group: "apps", Resource: "deployments"}, "my-app", goerrors.New("stale resource version")))
+	conflicts, ok := FieldConflicts(conflictErr)
+	if !ok {
+		t.Fatalf("FieldConflicts did not recognise %v as a conflict error", conflictErr)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("got %d conflicts, want 0 for an error with no Causes", len(conflicts))
+	}
+}
+
+func TestPatchType(t *testing.T) {
+	if pt := patchType(ApplyOptions{}); pt != types.StrategicMergePatchType {
+		t.Fatalf("got %v, want StrategicMergePatchType", pt)
+	}
+	if pt := patchType(ApplyOptions{ServerSideApply: true}); pt != types.ApplyPatchType {
+		t.Fatalf("got %v, want ApplyPatchType", pt)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// watchUntilReady does a List-then-Watch of a single named object and
+// blocks until sync reports it ready, ctx is cancelled or timeout elapses.
+// The initial List is replayed as synthetic Added events by
+// watchtools.UntilWithSync, so an object that is already ready by the time
+// Wait is called (e.g. a ClusterRole, or anything whose status doesn't
+// change again after the Apply that just ran) is still observed, unlike a
+// bare Watch which only sees events from "now" on. Each Resource's Wait
+// method uses this instead of polling Get in a loop.
+func watchUntilReady(
+	ctx context.Context,
+	timeout time.Duration,
+	name string,
+	listFunc func(context.Context, metav1.ListOptions) (runtime.Object, error),
+	watchFunc func(context.Context, metav1.ListOptions) (watch.Interface, error),
+	sync func(watch.Event) (bool, error),
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(lo metav1.ListOptions) (runtime.Object, error) {
+			lo.FieldSelector = fieldSelector
+			return listFunc(ctx, lo)
+		},
+		WatchFunc: func(lo metav1.ListOptions) (watch.Interface, error) {
+			lo.FieldSelector = fieldSelector
+			return watchFunc(ctx, lo)
+		},
+	}
+
+	_, err := watchtools.UntilWithSync(ctx, lw, nil, nil, sync)
+	return errors.Trace(err)
+}
+
+// ApplyOptions configures the behaviour of a single Resource.Apply call, and
+// of ApplyOrdered across a batch of resources.
+type ApplyOptions struct {
+	// Wait, when true, blocks after applying each batch of resources of a
+	// given kind until every resource in that batch reports status.Active.
+	// Only used by ApplyOrdered.
+	Wait bool
+	// Timeout bounds how long Wait will block for a single resource. Only
+	// used by ApplyOrdered.
+	Timeout time.Duration
+
+	// ServerSideApply selects the Server-Side Apply patch strategy
+	// (types.ApplyPatchType) instead of the default strategic merge patch.
+	ServerSideApply bool
+	// Force, combined with ServerSideApply, takes ownership of fields
+	// currently owned by another field manager instead of returning a
+	// conflict error.
+	Force bool
+
+	// Claims, when set, are asserted against any existing object before
+	// Apply patches it, so Apply refuses to silently take over an object
+	// it doesn't own. See assertClaims.
+	Claims []Claim
+}
+
+// applyOrder lists resource kinds in the order Helm installs them in, so
+// that e.g. a Deployment's ServiceAccount already exists by the time the
+// Deployment is applied.
+var applyOrder = []string{
+	"Namespace",
+	"ServiceAccount",
+	"Role",
+	"ClusterRole",
+	"RoleBinding",
+	"ClusterRoleBinding",
+	"ConfigMap",
+	"Secret",
+	"PersistentVolumeClaim",
+	"Service",
+	"DaemonSet",
+	"Deployment",
+	"StatefulSet",
+	"Job",
+}
+
+// ApplyOrdered applies the given resources in Helm's install order, batching
+// resources of the same kind together. When opts.Wait is set, it waits for
+// every resource in a batch to become ready before moving on to the next
+// kind.
+func ApplyOrdered(ctx context.Context, resources []Resource, opts ApplyOptions) error {
+	batches := make(map[string][]Resource, len(applyOrder))
+	for _, r := range resources {
+		kind := r.ID().Kind
+		batches[kind] = append(batches[kind], r)
+	}
+
+	order := append([]string{}, applyOrder...)
+	var extra []string
+	for kind := range batches {
+		found := false
+		for _, k := range order {
+			if k == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, kind)
+		}
+	}
+	// Kinds not covered by applyOrder (e.g. arbitrary CRDs applied via
+	// DynamicResource) have no defined install order of their own, so sort
+	// them for a deterministic batch order instead of relying on map
+	// iteration order.
+	sort.Strings(extra)
+	order = append(order, extra...)
+
+	for _, kind := range order {
+		batch := batches[kind]
+		for _, r := range batch {
+			if err := r.Apply(ctx, opts); err != nil {
+				return errors.Annotatef(err, "applying %s %q", kind, r.ID().Name)
+			}
+		}
+		if !opts.Wait {
+			continue
+		}
+		for _, r := range batch {
+			if err := r.Wait(ctx, opts.Timeout); err != nil {
+				return errors.Annotatef(err, "waiting for %s %q", kind, r.ID().Name)
+			}
+		}
+	}
+	return nil
+}
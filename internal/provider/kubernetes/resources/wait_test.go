@@ -0,0 +1,106 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/juju/juju/core/status"
+	"github.com/juju/juju/internal/provider/kubernetes/resources"
+)
+
+// fakeResource is a minimal resources.Resource that records the order it
+// was applied in, so ApplyOrdered's batching/ordering can be tested without
+// a real Kubernetes client.
+type fakeResource struct {
+	id  resources.ID
+	log *[]string
+}
+
+func (f *fakeResource) DeleteOrphan(ctx context.Context) error { return nil }
+
+func (f *fakeResource) Clone() resources.Resource {
+	clone := *f
+	return &clone
+}
+
+func (f *fakeResource) ID() resources.ID { return f.id }
+
+func (f *fakeResource) Get(ctx context.Context) error { return nil }
+
+func (f *fakeResource) Delete(ctx context.Context) error { return nil }
+
+func (f *fakeResource) Ensure(ctx context.Context, claims ...resources.Claim) ([]func(), error) {
+	return nil, nil
+}
+
+func (f *fakeResource) Update(ctx context.Context) error { return nil }
+
+func (f *fakeResource) ComputeStatus(ctx context.Context, now time.Time) (string, status.Status, time.Time, error) {
+	return "", status.Active, now, nil
+}
+
+func (f *fakeResource) Apply(ctx context.Context, opts ...resources.ApplyOptions) error {
+	*f.log = append(*f.log, f.id.Kind)
+	return nil
+}
+
+func (f *fakeResource) Wait(ctx context.Context, timeout time.Duration) error { return nil }
+
+func TestApplyOrderedSortsKindsNotInApplyOrder(t *testing.T) {
+	var log []string
+	newResource := func(kind, name string) resources.Resource {
+		return &fakeResource{id: resources.ID{Kind: kind, Name: name}, log: &log}
+	}
+
+	rs := []resources.Resource{
+		newResource("Widget", "w1"),
+		newResource("Deployment", "d1"),
+		newResource("Gadget", "g1"),
+		newResource("ClusterRole", "cr1"),
+	}
+
+	if err := resources.ApplyOrdered(context.Background(), rs, resources.ApplyOptions{}); err != nil {
+		t.Fatalf("ApplyOrdered: %v", err)
+	}
+
+	// ClusterRole and Deployment come from applyOrder; Gadget and Widget
+	// aren't in it, so they're sorted alphabetically and applied last.
+	want := []string{"ClusterRole", "Deployment", "Gadget", "Widget"}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("got apply order %v, want %v", log, want)
+	}
+}
+
+func TestApplyOrderedIsDeterministicAcrossRuns(t *testing.T) {
+	newResource := func(log *[]string, kind, name string) resources.Resource {
+		return &fakeResource{id: resources.ID{Kind: kind, Name: name}, log: log}
+	}
+
+	var first, second []string
+	rs1 := []resources.Resource{
+		newResource(&first, "Zebra", "z1"),
+		newResource(&first, "Apple", "a1"),
+		newResource(&first, "Service", "s1"),
+	}
+	rs2 := []resources.Resource{
+		newResource(&second, "Apple", "a1"),
+		newResource(&second, "Zebra", "z1"),
+		newResource(&second, "Service", "s1"),
+	}
+
+	if err := resources.ApplyOrdered(context.Background(), rs1, resources.ApplyOptions{}); err != nil {
+		t.Fatalf("ApplyOrdered: %v", err)
+	}
+	if err := resources.ApplyOrdered(context.Background(), rs2, resources.ApplyOptions{}); err != nil {
+		t.Fatalf("ApplyOrdered: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("apply order was not deterministic: %v vs %v", first, second)
+	}
+}
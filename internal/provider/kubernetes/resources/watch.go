@@ -0,0 +1,157 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/juju/juju/core/status"
+)
+
+// ResourceEventType categorises a ResourceEvent the way client-go's
+// reflector categorises the underlying Kubernetes watch events.
+type ResourceEventType string
+
+const (
+	// ResourceAdded is emitted the first time a resource is observed,
+	// including during the initial List that seeds the watch.
+	ResourceAdded ResourceEventType = "Added"
+	// ResourceModified is emitted whenever a previously observed resource
+	// changes.
+	ResourceModified ResourceEventType = "Modified"
+	// ResourceDeleted is emitted when a resource is removed from the
+	// cluster.
+	ResourceDeleted ResourceEventType = "Deleted"
+)
+
+// ResourceEvent reports a change to a watched resource, along with its
+// juju status computed at the time of the event, so callers like the CAAS
+// controller don't need to call ComputeStatus themselves.
+type ResourceEvent struct {
+	Type     ResourceEventType
+	Resource Resource
+	Status   status.Status
+	Message  string
+}
+
+// watchResources runs a List-then-Watch informer over lw and emits a
+// ResourceEvent for every Add/Update/Delete, until ctx is cancelled. This
+// gives callers a push-based feed instead of the paginated one-shot List
+// the ListXxx functions perform.
+func watchResources(
+	ctx context.Context,
+	lw *cache.ListWatch,
+	objType runtime.Object,
+	toResource func(obj interface{}) Resource,
+) <-chan ResourceEvent {
+	events := make(chan ResourceEvent)
+
+	emit := func(t ResourceEventType, obj interface{}) {
+		r := toResource(obj)
+		message, s, _, err := r.ComputeStatus(ctx, time.Now())
+		if err != nil {
+			s = status.Error
+			message = err.Error()
+		}
+		select {
+		case events <- ResourceEvent{Type: t, Resource: r, Status: s, Message: message}:
+		case <-ctx.Done():
+		}
+	}
+
+	_, informer := cache.NewInformer(lw, objType, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { emit(ResourceAdded, obj) },
+		UpdateFunc: func(_, obj interface{}) { emit(ResourceModified, obj) },
+		DeleteFunc: func(obj interface{}) {
+			// A delete missed while the watch was down arrives as a tombstone
+			// wrapping the last known state of the object.
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			emit(ResourceDeleted, obj)
+		},
+	})
+
+	stopCh := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stopCh)
+	}()
+	go func() {
+		informer.Run(stopCh)
+		close(events)
+	}()
+
+	return events
+}
+
+// WatchDaemonSets returns a channel of events for daemon sets matching
+// opts in namespace, seeded with an Added event per existing daemon set.
+func WatchDaemonSets(ctx context.Context, client v1.DaemonSetInterface, namespace string, opts metav1.ListOptions) (<-chan ResourceEvent, error) {
+	lw := &cache.ListWatch{
+		ListFunc: func(lo metav1.ListOptions) (runtime.Object, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.List(ctx, lo)
+		},
+		WatchFunc: func(lo metav1.ListOptions) (watch.Interface, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.Watch(ctx, lo)
+		},
+	}
+	events := watchResources(ctx, lw, &appsv1.DaemonSet{}, func(obj interface{}) Resource {
+		ds := obj.(*appsv1.DaemonSet)
+		return NewDaemonSet(client, namespace, ds.Name, ds)
+	})
+	return events, nil
+}
+
+// WatchDeployments returns a channel of events for deployments matching
+// opts in namespace, seeded with an Added event per existing deployment.
+func WatchDeployments(ctx context.Context, client v1.DeploymentInterface, namespace string, opts metav1.ListOptions) (<-chan ResourceEvent, error) {
+	lw := &cache.ListWatch{
+		ListFunc: func(lo metav1.ListOptions) (runtime.Object, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.List(ctx, lo)
+		},
+		WatchFunc: func(lo metav1.ListOptions) (watch.Interface, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.Watch(ctx, lo)
+		},
+	}
+	events := watchResources(ctx, lw, &appsv1.Deployment{}, func(obj interface{}) Resource {
+		d := obj.(*appsv1.Deployment)
+		return NewDeployment(client, namespace, d.Name, d)
+	})
+	return events, nil
+}
+
+// WatchClusterRoles returns a channel of events for cluster roles matching
+// opts, seeded with an Added event per existing cluster role.
+func WatchClusterRoles(ctx context.Context, client rbacv1client.ClusterRoleInterface, opts metav1.ListOptions) (<-chan ResourceEvent, error) {
+	lw := &cache.ListWatch{
+		ListFunc: func(lo metav1.ListOptions) (runtime.Object, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.List(ctx, lo)
+		},
+		WatchFunc: func(lo metav1.ListOptions) (watch.Interface, error) {
+			lo.LabelSelector, lo.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return client.Watch(ctx, lo)
+		},
+	}
+	events := watchResources(ctx, lw, &rbacv1.ClusterRole{}, func(obj interface{}) Resource {
+		r := obj.(*rbacv1.ClusterRole)
+		return NewClusterRole(client, r.Name, r)
+	})
+	return events, nil
+}